@@ -0,0 +1,113 @@
+package intent
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+// historyLimit bounds the number of HistoryEntry records retained on a Node
+// so its annotation doesn't grow unbounded over the life of a cluster.
+const historyLimit = 10
+
+// maxRetryAttempts is the number of times an errored action may be reattempted
+// before Retryable defers to escalation instead.
+const maxRetryAttempts = 3
+
+// HistoryEntry records a single step previously taken on an Intent, keeping
+// enough detail to diagnose a stuck or errored node after the fact instead of
+// requiring an operator to reset it blindly.
+type HistoryEntry struct {
+	Wanted    marker.NodeAction
+	Active    marker.NodeAction
+	State     marker.NodeState
+	Timestamp time.Time
+	Attempt   int
+	Error     string
+}
+
+// History returns the Intent's recorded progression, oldest first, as last
+// observed on the Node. It is bounded to historyLimit entries.
+func (i *Intent) History() []HistoryEntry {
+	return i.history
+}
+
+// LastError returns the Error of the most recent HistoryEntry that recorded
+// one, or the empty string if no recorded step has errored.
+func (i *Intent) LastError() string {
+	for idx := len(i.history) - 1; idx >= 0; idx-- {
+		if i.history[idx].Error != "" {
+			return i.history[idx].Error
+		}
+	}
+	return ""
+}
+
+// AttemptCount reports how many recorded HistoryEntry entries were taken
+// while the given action was Active.
+func (i *Intent) AttemptCount(action marker.NodeAction) int {
+	var count int
+	for _, h := range i.history {
+		if h.Active == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Retryable reports whether the Intent's current error should be retried
+// rather than escalated to an operator, based on how many times the Active
+// action has already been attempted.
+func (i *Intent) Retryable() bool {
+	if !i.Errored() {
+		return false
+	}
+	return i.AttemptCount(i.Active) < maxRetryAttempts
+}
+
+// Record appends a HistoryEntry describing the Intent's current situation,
+// trimming the oldest entries once historyLimit is exceeded. errMsg should be
+// empty for a successful step.
+func (i *Intent) Record(errMsg string) {
+	entry := HistoryEntry{
+		Wanted:    i.Wanted,
+		Active:    i.Active,
+		State:     i.State,
+		Timestamp: time.Now(),
+		Attempt:   i.AttemptCount(i.Active) + 1,
+		Error:     errMsg,
+	}
+	i.history = append(i.history, entry)
+	if len(i.history) > historyLimit {
+		i.history = i.history[len(i.history)-historyLimit:]
+	}
+}
+
+// marshalHistory encodes the history for storage in an annotation. It
+// returns the empty string for an empty history so the annotation is simply
+// omitted rather than stored as "null" or "[]".
+func marshalHistory(h []HistoryEntry) string {
+	if len(h) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(h)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// unmarshalHistory decodes a history annotation, returning nil for an empty
+// or malformed value rather than erroring, consistent with the zero-value
+// handling the rest of Given affords a freshly seen Node.
+func unmarshalHistory(raw string) []HistoryEntry {
+	if raw == "" {
+		return nil
+	}
+	var h []HistoryEntry
+	if err := json.Unmarshal([]byte(raw), &h); err != nil {
+		return nil
+	}
+	return h
+}
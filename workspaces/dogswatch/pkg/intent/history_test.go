@@ -0,0 +1,96 @@
+package intent
+
+import (
+	"testing"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+func TestRecordAndHistoryAccessors(t *testing.T) {
+	i := &Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPrepareUpdate,
+		Active:   marker.NodeActionPrepareUpdate,
+		State:    marker.NodeStateError,
+	}
+
+	i.Record("")
+	i.Record("boom")
+	i.Record("boom again")
+
+	if got, want := len(i.History()), 3; got != want {
+		t.Fatalf("len(History()) = %d, want %d", got, want)
+	}
+	if got, want := i.LastError(), "boom again"; got != want {
+		t.Errorf("LastError() = %q, want %q", got, want)
+	}
+	if got, want := i.AttemptCount(marker.NodeActionPrepareUpdate), 3; got != want {
+		t.Errorf("AttemptCount() = %d, want %d", got, want)
+	}
+	if got, want := i.History()[2].Attempt, 3; got != want {
+		t.Errorf("third entry Attempt = %d, want %d", got, want)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	i := &Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPrepareUpdate,
+		Active:   marker.NodeActionPrepareUpdate,
+		State:    marker.NodeStateError,
+	}
+
+	for n := 0; n < maxRetryAttempts; n++ {
+		if !i.Retryable() {
+			t.Fatalf("Retryable() = false on attempt %d, want true (< %d prior attempts)", n, maxRetryAttempts)
+		}
+		i.Record("boom")
+	}
+	if i.Retryable() {
+		t.Errorf("Retryable() = true after %d attempts, want false", maxRetryAttempts)
+	}
+}
+
+func TestRetryableWhenNotErrored(t *testing.T) {
+	i := &Intent{State: marker.NodeStateReady}
+	if i.Retryable() {
+		t.Error("Retryable() = true for a non-errored intent, want false")
+	}
+}
+
+func TestHistoryBoundedToLimit(t *testing.T) {
+	i := &Intent{NodeName: "node-a", Active: marker.NodeActionPrepareUpdate}
+	for n := 0; n < historyLimit+5; n++ {
+		i.Record("")
+	}
+	if got, want := len(i.History()), historyLimit; got != want {
+		t.Errorf("len(History()) = %d, want bounded to %d", got, want)
+	}
+}
+
+func TestHistoryAnnotationRoundTrip(t *testing.T) {
+	i := &Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPrepareUpdate,
+		Active:   marker.NodeActionPrepareUpdate,
+		State:    marker.NodeStateError,
+	}
+	i.Record("boom")
+
+	annos := i.GetAnnotations()
+	encoded, ok := annos[marker.NodeActionHistory]
+	if !ok {
+		t.Fatal("GetAnnotations() did not include marker.NodeActionHistory")
+	}
+
+	got := unmarshalHistory(encoded)
+	if len(got) != 1 || got[0].Error != "boom" {
+		t.Errorf("unmarshalHistory() = %+v, want a single entry with Error=boom", got)
+	}
+}
+
+func TestMarshalHistoryOmitsEmpty(t *testing.T) {
+	if got := marshalHistory(nil); got != "" {
+		t.Errorf("marshalHistory(nil) = %q, want empty string", got)
+	}
+}
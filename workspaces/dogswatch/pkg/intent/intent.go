@@ -4,14 +4,17 @@ import (
 	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
 )
 
-// TODO: encapsulate state machine-y handling. Callers should not have to
-// reference marker to compare the needed state nor set the necessary response.
-
 // Intent is a pseudo-Container of Labels and Annotations.
 var _ marker.Container = (*Intent)(nil)
 
 // Intent is the sole communicator of state progression and desired intentions
 // for an Agent to act upon and to communicate its progress.
+//
+// The forward progression table previously implicit here now lives behind
+// DefaultStateMachine: Projected and Terminal consult it via NextWanted
+// rather than calling calculateNext directly, and callers that need a single
+// decision point covering errored/stuck/reset handling too should prefer
+// DefaultStateMachine.Step over combining the predicates below themselves.
 type Intent struct {
 	// NodeName is the Resource name that addresses it.
 	NodeName string
@@ -24,6 +27,13 @@ type Intent struct {
 	State marker.NodeState
 	// UpdateAvailable is the Node's status of having an update ready to apply.
 	UpdateAvailable marker.NodeUpdate
+	// Progress reports the staged sub-steps of the Active action, as last
+	// reported by the Agent working it.
+	Progress Progress
+	// history is the bounded, ordered record of Steps previously applied to
+	// this Intent, most recent last. It round-trips through
+	// marker.NodeActionHistory so it survives across controller restarts.
+	history []HistoryEntry
 }
 
 // GetName returns the name of the Intent's target.
@@ -34,12 +44,19 @@ func (i *Intent) GetName() string {
 // GetAnnotations transposes the Intent into a map of Annotations suitable for
 // adding to a Resource.
 func (i *Intent) GetAnnotations() map[string]string {
-	return map[string]string{
+	annos := map[string]string{
 		marker.NodeActionWanted:      i.Wanted,
 		marker.NodeActionActive:      i.Active,
 		marker.NodeActionActiveState: i.State,
 		marker.UpdateAvailableKey:    i.UpdateAvailable,
 	}
+	if encoded := marshalHistory(i.history); encoded != "" {
+		annos[marker.NodeActionHistory] = encoded
+	}
+	if encoded := marshalProgress(i.Progress); encoded != "" {
+		annos[marker.NodeActionProgress] = encoded
+	}
+	return annos
 }
 
 // GetLabels transposes the Intent into a map of Labels suitable for adding to a
@@ -78,8 +95,11 @@ func (i *Intent) Waiting() bool {
 
 // Intrusive indicates that the intention will be intrusive if realized.
 func (i *Intent) Intrusive() bool {
-	rebooting := i.Wanted == marker.NodeActionRebootUpdate
-	return rebooting
+	switch i.Wanted {
+	case marker.NodeActionRebootUpdate, marker.NodeActionPerformUpdate:
+		return true
+	}
+	return false
 }
 
 // Errored indicates that the intention was not realized and failed in attempt
@@ -152,7 +172,7 @@ func (i *Intent) Projected() *Intent {
 	if p.inUnknownState() {
 		p.reset()
 	}
-	p.Wanted, _ = calculateNext(p.Wanted)
+	p.Wanted, _ = DefaultStateMachine.NextWanted(p, p.Wanted)
 	return p
 }
 
@@ -171,7 +191,7 @@ func (i *Intent) inUnknownState() bool {
 // progression, the intent will not make progress in anyway without outside
 // state action.
 func (i *Intent) Terminal() bool {
-	next, err := calculateNext(i.Wanted)
+	next, err := DefaultStateMachine.NextWanted(i, i.Wanted)
 	if err != nil {
 		return false
 	}
@@ -198,9 +218,14 @@ func (i *Intent) reset() {
 }
 
 // Clone returns a copy of the Intent to mutate independently of the source
-// instance.
+// instance. Fields are copied directly rather than round-tripped through
+// GetAnnotations/Given: Clone sits on the hot path of every StateMachine
+// decision (Step, NextWanted, Projected, Trace), and JSON-encoding/decoding
+// history and Progress on every call would be wasted work.
 func (i Intent) Clone() *Intent {
-	return Given(&i)
+	clone := i
+	clone.history = append([]HistoryEntry(nil), i.history...)
+	return &clone
 }
 
 // Given determines the commuincated intent from a Node without projecting into
@@ -214,6 +239,8 @@ func Given(input Input) *Intent {
 		Wanted:          annos[marker.NodeActionWanted],
 		State:           annos[marker.NodeActionActiveState],
 		UpdateAvailable: annos[marker.UpdateAvailableKey],
+		Progress:        unmarshalProgress(annos[marker.NodeActionProgress]),
+		history:         unmarshalHistory(annos[marker.NodeActionHistory]),
 	}
 
 	return intent
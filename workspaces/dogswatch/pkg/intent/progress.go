@@ -0,0 +1,100 @@
+package intent
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+// Progress reports the staged sub-steps of the Intent's current Active
+// action, giving the controller richer information than the binary
+// InProgress predicate alone.
+type Progress struct {
+	Step       int
+	TotalSteps int
+	Phase      string
+	Message    string
+	StartedAt  time.Time
+}
+
+// Phases enumerates the ordered sub-steps an Agent is expected to report via
+// Advance for each NodeAction that reports staged progress. An action absent from
+// this table is treated as a single, unstaged step.
+var Phases = map[marker.NodeAction][]string{
+	marker.NodeActionPrepareUpdate: {"download", "verify", "stage"},
+	marker.NodeActionPerformUpdate: {"apply"},
+	marker.NodeActionRebootUpdate:  {"drain", "reboot", "uncordon"},
+}
+
+// Advance records that the Intent's Active action has moved on to phase,
+// resetting the staleness clock Stalled checks against. A phase unknown to
+// the Active action's entry in Phases still updates Phase and TotalSteps,
+// but resets Step to 0 rather than leaving it stale against the new
+// TotalSteps.
+func (i *Intent) Advance(phase string) {
+	phases := Phases[i.Active]
+	i.Progress.StartedAt = time.Now()
+	i.Progress.Phase = phase
+	i.Progress.TotalSteps = len(phases)
+	if idx := indexOfPhase(phases, phase); idx >= 0 {
+		i.Progress.Step = idx + 1
+	} else {
+		i.Progress.Step = 0
+	}
+}
+
+// PercentComplete reports how far through its staged sub-steps the Active
+// action has progressed, or 0 when the action has no staged Phases.
+func (i *Intent) PercentComplete() float64 {
+	if i.Progress.TotalSteps == 0 {
+		return 0
+	}
+	return float64(i.Progress.Step) * 100 / float64(i.Progress.TotalSteps)
+}
+
+// Stalled reports whether the Intent is InProgress but hasn't recorded an
+// Advance within the given threshold, suggesting the Agent working it has
+// stopped making progress.
+func (i *Intent) Stalled(threshold time.Duration) bool {
+	if i.Progress.StartedAt.IsZero() {
+		return false
+	}
+	return i.InProgress() && time.Since(i.Progress.StartedAt) > threshold
+}
+
+func indexOfPhase(phases []string, phase string) int {
+	for idx, p := range phases {
+		if p == phase {
+			return idx
+		}
+	}
+	return -1
+}
+
+// marshalProgress encodes the Progress for storage in an annotation. It
+// returns the empty string for a zero-value Progress so the annotation is
+// simply omitted.
+func marshalProgress(p Progress) string {
+	if p == (Progress{}) {
+		return ""
+	}
+	encoded, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// unmarshalProgress decodes a Progress annotation, returning the zero value
+// for an empty or malformed entry.
+func unmarshalProgress(raw string) Progress {
+	var p Progress
+	if raw == "" {
+		return p
+	}
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return Progress{}
+	}
+	return p
+}
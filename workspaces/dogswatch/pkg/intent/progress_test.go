@@ -0,0 +1,94 @@
+package intent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+func TestAdvancePercentComplete(t *testing.T) {
+	i := &Intent{Active: marker.NodeActionPrepareUpdate}
+
+	i.Advance("download")
+	if got, want := i.Progress.Step, 1; got != want {
+		t.Errorf("Step = %d, want %d", got, want)
+	}
+	if got, want := i.PercentComplete(), 100.0/3; got != want {
+		t.Errorf("PercentComplete() = %v, want %v", got, want)
+	}
+
+	i.Advance("stage")
+	if got, want := i.Progress.Step, 3; got != want {
+		t.Errorf("Step = %d, want %d", got, want)
+	}
+}
+
+func TestAdvanceUnknownPhaseResetsStep(t *testing.T) {
+	i := &Intent{Active: marker.NodeActionPrepareUpdate}
+	i.Advance("stage")
+	if i.Progress.Step != 3 {
+		t.Fatalf("fixture setup failed, Step = %d", i.Progress.Step)
+	}
+
+	// Active changes to a different staged action and the Agent reports a
+	// phase that isn't in its Phases entry (eg: a typo). Step must not be
+	// left stale against the new TotalSteps.
+	i.Active = marker.NodeActionRebootUpdate
+	i.Advance("not-a-real-phase")
+
+	if got, want := i.Progress.Step, 0; got != want {
+		t.Errorf("Step = %d after unmatched phase, want %d", got, want)
+	}
+	if got, want := i.PercentComplete(), 0.0; got != want {
+		t.Errorf("PercentComplete() = %v after unmatched phase, want %v", got, want)
+	}
+}
+
+func TestStalled(t *testing.T) {
+	// Active is mid-progress on PrepareUpdate with Wanted already advanced
+	// to PerformUpdate (the same fixture shape InProgress's own doc comment
+	// describes), so InProgress() is true and Stalled can key off elapsed
+	// time alone. An update must be available, or the guard on entering
+	// PerformUpdate keeps the projection at PrepareUpdate.
+	i := &Intent{
+		Wanted:          marker.NodeActionPerformUpdate,
+		Active:          marker.NodeActionPrepareUpdate,
+		State:           marker.NodeStateReady,
+		UpdateAvailable: marker.NodeUpdateAvailable,
+	}
+	if !i.InProgress() {
+		t.Fatal("test fixture intent is not InProgress(), fix the fixture")
+	}
+	i.Advance("download")
+	i.Progress.StartedAt = time.Now().Add(-time.Hour)
+
+	if !i.Stalled(time.Minute) {
+		t.Error("Stalled(1m) = false, want true for a phase started an hour ago")
+	}
+	if i.Stalled(2 * time.Hour) {
+		t.Error("Stalled(2h) = true, want false for a phase started an hour ago")
+	}
+}
+
+func TestProgressAnnotationRoundTrip(t *testing.T) {
+	i := &Intent{NodeName: "node-a", Active: marker.NodeActionPrepareUpdate}
+	i.Advance("verify")
+
+	annos := i.GetAnnotations()
+	encoded, ok := annos[marker.NodeActionProgress]
+	if !ok {
+		t.Fatal("GetAnnotations() did not include marker.NodeActionProgress")
+	}
+
+	got := unmarshalProgress(encoded)
+	if got.Phase != "verify" || got.Step != 2 || got.TotalSteps != 3 {
+		t.Errorf("unmarshalProgress() = %+v, want Phase=verify Step=2 TotalSteps=3", got)
+	}
+}
+
+func TestMarshalProgressOmitsZeroValue(t *testing.T) {
+	if got := marshalProgress(Progress{}); got != "" {
+		t.Errorf("marshalProgress(zero value) = %q, want empty string", got)
+	}
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+)
+
+// MaxUnavailableCount admits a candidate only if fewer than Max Nodes are
+// already unavailable across the cluster.
+type MaxUnavailableCount struct {
+	Max int
+}
+
+// Admit implements Scheduler.
+func (m MaxUnavailableCount) Admit(candidate *intent.Intent, cluster []*intent.Intent) (bool, string) {
+	count := unavailable(cluster)
+	if count >= m.Max {
+		return false, fmt.Sprintf("%d nodes already unavailable, max %d", count, m.Max)
+	}
+	return true, ""
+}
+
+// MaxUnavailablePercent admits a candidate only if fewer Nodes are already
+// unavailable than Percent of the cluster's Nodes, rounded down. At least
+// one Node is always permitted to be unavailable, regardless of how small
+// Percent of the cluster that amounts to.
+type MaxUnavailablePercent struct {
+	Percent float64
+}
+
+// Admit implements Scheduler.
+func (m MaxUnavailablePercent) Admit(candidate *intent.Intent, cluster []*intent.Intent) (bool, string) {
+	if len(cluster) == 0 {
+		return true, ""
+	}
+	count := unavailable(cluster)
+	allowed := int(float64(len(cluster)) * m.Percent / 100)
+	if allowed < 1 {
+		allowed = 1
+	}
+	if count >= allowed {
+		return false, fmt.Sprintf("%d of %d nodes unavailable, max %.0f%% (%d)", count, len(cluster), m.Percent, allowed)
+	}
+	return true, ""
+}
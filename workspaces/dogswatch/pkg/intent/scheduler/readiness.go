@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+)
+
+// Workload reports how ready a Node's scheduled workloads are to tolerate
+// the Node becoming unavailable.
+type Workload interface {
+	// DrainableFraction returns the fraction, in [0,1], of Pods scheduled on
+	// the named Node that may be safely evicted right now (eg: covered by
+	// enough ready replicas elsewhere to satisfy their PodDisruptionBudget).
+	DrainableFraction(nodeName string) float64
+}
+
+// WorkloadReadiness admits a candidate only once its Workload reports
+// sufficient drainable capacity, rather than assuming every Node is equally
+// safe to take down.
+type WorkloadReadiness struct {
+	Workload     Workload
+	MinDrainable float64
+}
+
+// Admit implements Scheduler.
+func (w WorkloadReadiness) Admit(candidate *intent.Intent, cluster []*intent.Intent) (bool, string) {
+	fraction := w.Workload.DrainableFraction(candidate.GetName())
+	if fraction < w.MinDrainable {
+		return false, fmt.Sprintf("only %.0f%% of node's workload is drainable, need %.0f%%", fraction*100, w.MinDrainable*100)
+	}
+	return true, ""
+}
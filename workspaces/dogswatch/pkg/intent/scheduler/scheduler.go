@@ -0,0 +1,46 @@
+// Package scheduler reasons over the set of Intents observed across a
+// cluster to decide which Nodes may proceed to an Intrusive action, so
+// rolling updates respect concurrency budgets instead of the implicit
+// one-at-a-time serialization the controller loop otherwise falls back to.
+package scheduler
+
+import "github.com/amazonlinux/thar/dogswatch/pkg/intent"
+
+// Scheduler decides whether a candidate Intent may be projected forward to
+// an Intrusive action, given the Intents observed for the rest of the
+// cluster.
+type Scheduler interface {
+	// Admit reports whether candidate may proceed and, when it may not, a
+	// human-readable reason suitable for surfacing on the Node.
+	Admit(candidate *intent.Intent, cluster []*intent.Intent) (bool, string)
+}
+
+// Chain composes multiple Schedulers, admitting a candidate only when every
+// member Scheduler admits it. This lets operators compose constraints (eg:
+// a cluster-wide budget alongside a per-AZ budget) rather than having to
+// encode them all into a single policy.
+type Chain []Scheduler
+
+// Admit runs each Scheduler in order, returning the first rejection
+// encountered. A Chain with no members always admits.
+func (c Chain) Admit(candidate *intent.Intent, cluster []*intent.Intent) (bool, string) {
+	for _, s := range c {
+		if ok, reason := s.Admit(candidate, cluster); !ok {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+// unavailable counts the Intents in cluster that are both Intrusive and
+// currently InProgress, ie: Nodes that are presently unavailable because
+// they're mid-way through a disruptive action.
+func unavailable(cluster []*intent.Intent) int {
+	var n int
+	for _, i := range cluster {
+		if i.Intrusive() && i.InProgress() {
+			n++
+		}
+	}
+	return n
+}
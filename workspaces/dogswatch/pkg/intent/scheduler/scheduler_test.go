@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+// unavailableIntent builds an Intent mid-progress towards wanted, with
+// Active set to the step immediately prior so it's genuinely InProgress()
+// rather than Stuck(). An update is marked available so the guard on
+// entering PerformUpdate doesn't block the projection.
+func unavailableIntent(name string, wanted marker.NodeAction) *intent.Intent {
+	active := wanted
+	switch wanted {
+	case marker.NodeActionPerformUpdate:
+		active = marker.NodeActionPrepareUpdate
+	case marker.NodeActionRebootUpdate:
+		active = marker.NodeActionPerformUpdate
+	}
+	return &intent.Intent{
+		NodeName:        name,
+		Wanted:          wanted,
+		Active:          active,
+		State:           marker.NodeStateReady,
+		UpdateAvailable: marker.NodeUpdateAvailable,
+	}
+}
+
+func TestUnavailableCountsPerformAndRebootUpdate(t *testing.T) {
+	a := unavailableIntent("a", marker.NodeActionPerformUpdate)
+	b := unavailableIntent("b", marker.NodeActionRebootUpdate)
+	c := &intent.Intent{NodeName: "c", Wanted: marker.NodeActionPrepareUpdate, Active: marker.NodeActionPrepareUpdate, State: marker.NodeStateReady}
+	for _, i := range []*intent.Intent{a, b} {
+		if !i.InProgress() {
+			t.Fatalf("test fixture %q is not InProgress(), fix the fixture", i.GetName())
+		}
+	}
+	if !c.Realized() {
+		t.Fatalf("test fixture %q is not Realized(), fix the fixture", c.GetName())
+	}
+
+	cluster := []*intent.Intent{a, b, c}
+	if got, want := unavailable(cluster), 2; got != want {
+		t.Errorf("unavailable() = %d, want %d (PerformUpdate and RebootUpdate both count, a Realized PrepareUpdate doesn't)", got, want)
+	}
+}
+
+func TestMaxUnavailableCount(t *testing.T) {
+	s := MaxUnavailableCount{Max: 1}
+	cluster := []*intent.Intent{unavailableIntent("a", marker.NodeActionPerformUpdate)}
+	candidate := &intent.Intent{NodeName: "b", Wanted: marker.NodeActionPerformUpdate}
+
+	if ok, reason := s.Admit(candidate, cluster); ok {
+		t.Errorf("Admit() = (true, %q), want rejection once Max is already reached", reason)
+	}
+
+	s.Max = 2
+	if ok, reason := s.Admit(candidate, cluster); !ok {
+		t.Errorf("Admit() = (false, %q), want admission under Max", reason)
+	}
+}
+
+func TestMaxUnavailablePercentAlwaysAllowsOne(t *testing.T) {
+	s := MaxUnavailablePercent{Percent: 1}
+	cluster := make([]*intent.Intent, 50)
+	for n := range cluster {
+		cluster[n] = &intent.Intent{NodeName: "node", Wanted: marker.NodeActionReset}
+	}
+	candidate := &intent.Intent{NodeName: "candidate", Wanted: marker.NodeActionPerformUpdate}
+
+	if ok, reason := s.Admit(candidate, cluster); !ok {
+		t.Errorf("Admit() = (false, %q), want the first node always admitted regardless of percent", reason)
+	}
+}
+
+func TestTopologyBudget(t *testing.T) {
+	topo := fakeTopology{"a": "zone-1", "b": "zone-1", "c": "zone-2"}
+	s := TopologyBudget{Topology: topo, Max: 1}
+
+	cluster := []*intent.Intent{unavailableIntent("a", marker.NodeActionRebootUpdate)}
+	candidateSameZone := &intent.Intent{NodeName: "b", Wanted: marker.NodeActionRebootUpdate}
+	candidateOtherZone := &intent.Intent{NodeName: "c", Wanted: marker.NodeActionRebootUpdate}
+
+	if ok, _ := s.Admit(candidateSameZone, cluster); ok {
+		t.Error("Admit() = true for a node sharing an already-unavailable zone, want rejection")
+	}
+	if ok, reason := s.Admit(candidateOtherZone, cluster); !ok {
+		t.Errorf("Admit() = (false, %q), want admission for a node in an unaffected zone", reason)
+	}
+}
+
+func TestWorkloadReadiness(t *testing.T) {
+	s := WorkloadReadiness{Workload: fakeWorkload{"a": 0.5}, MinDrainable: 0.9}
+	candidate := &intent.Intent{NodeName: "a"}
+
+	if ok, _ := s.Admit(candidate, nil); ok {
+		t.Error("Admit() = true below MinDrainable, want rejection")
+	}
+
+	s.MinDrainable = 0.5
+	if ok, reason := s.Admit(candidate, nil); !ok {
+		t.Errorf("Admit() = (false, %q), want admission at exactly MinDrainable", reason)
+	}
+}
+
+func TestChainRejectsOnFirstFailure(t *testing.T) {
+	chain := Chain{
+		MaxUnavailableCount{Max: 100},
+		MaxUnavailableCount{Max: 0},
+		WorkloadReadiness{Workload: fakeWorkload{}, MinDrainable: 1},
+	}
+	candidate := &intent.Intent{NodeName: "a"}
+
+	ok, reason := chain.Admit(candidate, nil)
+	if ok {
+		t.Fatal("Admit() = true, want the Max: 0 member to reject")
+	}
+	if reason == "" {
+		t.Error("Admit() returned an empty reason on rejection")
+	}
+}
+
+func TestChainAdmitsWhenEmpty(t *testing.T) {
+	var chain Chain
+	if ok, reason := chain.Admit(&intent.Intent{}, nil); !ok {
+		t.Errorf("Admit() = (false, %q), want an empty Chain to always admit", reason)
+	}
+}
+
+type fakeTopology map[string]string
+
+func (f fakeTopology) TopologyValue(nodeName string) string { return f[nodeName] }
+
+type fakeWorkload map[string]float64
+
+func (f fakeWorkload) DrainableFraction(nodeName string) float64 { return f[nodeName] }
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+)
+
+// Topology resolves the value a named Node holds for some topology key (eg:
+// the availability zone in "topology.kubernetes.io/zone"), typically read
+// off the Node's labels.
+type Topology interface {
+	TopologyValue(nodeName string) string
+}
+
+// TopologyBudget admits a candidate only if fewer than Max Nodes sharing its
+// topology value are already unavailable, so a rolling update doesn't take
+// down an entire AZ (or other topology domain) at once.
+type TopologyBudget struct {
+	Topology Topology
+	Max      int
+}
+
+// Admit implements Scheduler.
+func (t TopologyBudget) Admit(candidate *intent.Intent, cluster []*intent.Intent) (bool, string) {
+	value := t.Topology.TopologyValue(candidate.GetName())
+	var count int
+	for _, i := range cluster {
+		if t.Topology.TopologyValue(i.GetName()) != value {
+			continue
+		}
+		if i.Intrusive() && i.InProgress() {
+			count++
+		}
+	}
+	if count >= t.Max {
+		return false, fmt.Sprintf("%d nodes in %q already unavailable, max %d", count, value, t.Max)
+	}
+	return true, ""
+}
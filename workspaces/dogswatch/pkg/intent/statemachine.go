@@ -0,0 +1,269 @@
+package intent
+
+import (
+	"fmt"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+// Edge identifies the kind of transition taken between two Nodes in the
+// progression. Edges are the typed complement to the ad hoc combinations of
+// Waiting, Realized, Actionable, and Stuck that callers previously had to
+// assemble themselves.
+type Edge string
+
+const (
+	// Advance moves an Intent forward to the next action in the ordinary
+	// progression, as computed by calculateNext.
+	Advance Edge = "Advance"
+	// Retry re-attempts the Intent's current Active action after an error.
+	Retry Edge = "Retry"
+	// Reset returns the Intent to its Origin so it may be driven forward
+	// again from a clean state.
+	Reset Edge = "Reset"
+	// Abort indicates the Intent cannot make further progress and must be
+	// unstuck by outside intervention.
+	Abort Edge = "Abort"
+)
+
+// Node addresses a single point in the progression by the tuple of fields
+// that together describe it.
+type Node struct {
+	Wanted          marker.NodeAction
+	Active          marker.NodeAction
+	State           marker.NodeState
+	UpdateAvailable marker.NodeUpdate
+}
+
+func nodeOf(i *Intent) Node {
+	return Node{
+		Wanted:          i.Wanted,
+		Active:          i.Active,
+		State:           i.State,
+		UpdateAvailable: i.UpdateAvailable,
+	}
+}
+
+// Transition records the Edge taken, and the Nodes on either side of it, for
+// a single Step (or a single hop in a Trace).
+type Transition struct {
+	Edge Edge
+	From Node
+	To   Node
+}
+
+func (t Transition) String() string {
+	return fmt.Sprintf("%s(%s -> %s)", t.Edge, t.From.Wanted, t.To.Wanted)
+}
+
+// Guard vets whether an edge may be taken for a given Intent. A Guard that
+// returns an error blocks the edge and the error is surfaced to the caller of
+// Step or Validate.
+type Guard func(*Intent) error
+
+// edgeFunc computes the destination action for an edge, given the Intent it
+// would apply to.
+type edgeFunc func(*Intent) (marker.NodeAction, error)
+
+type registration struct {
+	edge   Edge
+	target edgeFunc
+	guards []Guard
+}
+
+// StateMachine owns the transition table that was previously implicit in
+// calculateNext and the Intent predicate methods (Waiting, Realized,
+// Actionable, Stuck, InProgress). Register additional edges and Guards to
+// extend the table without touching the predicate methods themselves.
+type StateMachine struct {
+	edges map[marker.NodeAction][]registration
+}
+
+// NewStateMachine returns an empty StateMachine with no registered edges.
+func NewStateMachine() *StateMachine {
+	return &StateMachine{
+		edges: make(map[marker.NodeAction][]registration),
+	}
+}
+
+// Register adds an edge from the provided action to the StateMachine's
+// table. Guards are evaluated in the order given and the first failing
+// Guard's error is returned from Step or Validate; the edge's target is only
+// computed once all Guards pass.
+func (s *StateMachine) Register(from marker.NodeAction, edge Edge, target edgeFunc, guards ...Guard) {
+	s.edges[from] = append(s.edges[from], registration{
+		edge:   edge,
+		target: target,
+		guards: guards,
+	})
+}
+
+// RegisterTo is a convenience for Register when the destination action is
+// fixed rather than computed from the Intent.
+func (s *StateMachine) RegisterTo(from marker.NodeAction, edge Edge, to marker.NodeAction, guards ...Guard) {
+	s.Register(from, edge, func(*Intent) (marker.NodeAction, error) { return to, nil }, guards...)
+}
+
+// lookup finds the first registered edge of the given kind for the Intent's
+// current Wanted action. It does not run the edge's Guards, and returns false
+// if no edge of that kind is registered.
+func (s *StateMachine) lookup(i *Intent, edge Edge) (registration, bool) {
+	for _, r := range s.edges[i.Wanted] {
+		if r.edge == edge {
+			return r, true
+		}
+	}
+	return registration{}, false
+}
+
+func (s *StateMachine) take(i *Intent, r registration) (Transition, error) {
+	for _, guard := range r.guards {
+		if err := guard(i); err != nil {
+			return Transition{}, err
+		}
+	}
+	to, err := r.target(i)
+	if err != nil {
+		return Transition{}, err
+	}
+	next := i.Clone()
+	next.Wanted = to
+	return Transition{Edge: r.edge, From: nodeOf(i), To: nodeOf(next)}, nil
+}
+
+// Step determines the single edge that applies to the Intent's current
+// situation and returns the Transition it describes. This is the single
+// decision point callers should use in place of combining Waiting, Realized,
+// Actionable, and Stuck themselves.
+func (s *StateMachine) Step(i *Intent) (Transition, error) {
+	edge := s.classify(i)
+	if r, ok := s.lookup(i, edge); ok {
+		return s.take(i, r)
+	}
+	// Fall back to a sane default when no edge has been registered to
+	// customize this action's handling. Retry and Abort must never be
+	// silently reinterpreted as Advance: doing so would move a stuck or
+	// errored Intent forward instead of retrying or blocking it, which is
+	// exactly the ad hoc behavior this table replaces.
+	switch edge {
+	case Reset:
+		return s.take(i, registration{edge: Reset, target: func(*Intent) (marker.NodeAction, error) {
+			return marker.NodeActionUnknown, nil
+		}})
+	case Retry:
+		return s.take(i, registration{edge: Retry, target: func(c *Intent) (marker.NodeAction, error) {
+			// Default retry stays in place on the current Wanted action so
+			// the Agent reattempts it, rather than advancing past a step
+			// that hasn't actually succeeded.
+			return c.Wanted, nil
+		}})
+	case Abort:
+		return Transition{}, fmt.Errorf("intent: %s is stuck on %s and needs intervention", i.NodeName, i.Wanted)
+	default:
+		return s.take(i, registration{edge: Advance, target: advanceFromWanted})
+	}
+}
+
+// advanceFromWanted is the unadorned calculateNext progression, adapted to
+// edgeFunc's signature for use as a registration's target.
+func advanceFromWanted(i *Intent) (marker.NodeAction, error) {
+	return calculateNext(i.Wanted)
+}
+
+// NextWanted computes the forward Advance destination for the given action,
+// applying any registered Advance override and its Guards, and falling back
+// to calculateNext when none is registered. Projected and Terminal both
+// consult this so the forward progression table lives in one place instead
+// of calling calculateNext directly.
+func (s *StateMachine) NextWanted(i *Intent, from marker.NodeAction) (marker.NodeAction, error) {
+	probe := i.Clone()
+	probe.Wanted = from
+	if r, ok := s.lookup(probe, Advance); ok {
+		for _, guard := range r.guards {
+			if err := guard(probe); err != nil {
+				return from, err
+			}
+		}
+		return r.target(probe)
+	}
+	return calculateNext(from)
+}
+
+// classify determines which Edge is in play for the Intent's current
+// situation by deferring to the existing predicates (inUnknownState,
+// Errored, Realized, Stuck) rather than reimplementing their reasoning here.
+// Realized is checked ahead of Stuck: Stuck's invalidProgress term is true
+// for any non-terminal Wanted == Active pair, which is also exactly the
+// shape of a routine "just finished this step successfully" intent, not
+// only a genuinely broken one.
+func (s *StateMachine) classify(i *Intent) Edge {
+	switch {
+	case i.inUnknownState():
+		return Reset
+	case i.Errored():
+		return Retry
+	case i.Realized():
+		return Advance
+	case i.Stuck():
+		return Abort
+	default:
+		return Advance
+	}
+}
+
+// Validate reports whether Step would succeed for the Intent: it runs Step and
+// returns only its error, discarding the resulting Transition. Besides a
+// failing Guard, this includes the error Step returns for an Abort.
+func (s *StateMachine) Validate(i *Intent) error {
+	_, err := s.Step(i)
+	return err
+}
+
+// Trace projects the Intent forward, edge by edge, until it reaches a
+// Terminal point, Step returns an error (a Guard rejects the next edge, or
+// the projected Intent classifies as Abort), or an edge makes no progress.
+// Only Wanted is carried forward between hops; Active and State stay as
+// observed, so once Wanted runs ahead of Active the trace usually ends with
+// Abort. It is primarily useful for diagnostics.
+func (s *StateMachine) Trace(i *Intent) []Transition {
+	var path []Transition
+	cur := i.Clone()
+	for !cur.Terminal() {
+		t, err := s.Step(cur)
+		if err != nil {
+			break
+		}
+		path = append(path, t)
+		if t.To == t.From {
+			// No forward progress is being made; stop rather than loop
+			// forever on a fixed point that Terminal didn't catch.
+			break
+		}
+		cur.Wanted = t.To.Wanted
+	}
+	return path
+}
+
+// DefaultStateMachine is the table of edges describing the standard node
+// update progression. Entering PerformUpdate is guarded against proceeding
+// unless an update has actually been reported as available.
+var DefaultStateMachine = buildDefaultStateMachine()
+
+func buildDefaultStateMachine() *StateMachine {
+	s := NewStateMachine()
+
+	requireUpdateAvailable := func(i *Intent) error {
+		if !i.HasUpdateAvailable() {
+			return fmt.Errorf("intent: %s is not permitted without an available update", marker.NodeActionPerformUpdate)
+		}
+		return nil
+	}
+
+	// The guard belongs on the edge that *enters* PerformUpdate (from
+	// PrepareUpdate), not the edge leaving it: that's the transition
+	// Projected/NextWanted actually take when advancing Wanted to
+	// PerformUpdate, and the one the request's own example calls out.
+	s.Register(marker.NodeActionPrepareUpdate, Advance, advanceFromWanted, requireUpdateAvailable)
+
+	return s
+}
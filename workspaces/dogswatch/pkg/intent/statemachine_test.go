@@ -0,0 +1,131 @@
+package intent
+
+import (
+	"testing"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+func TestStepRetryFallbackStaysInPlace(t *testing.T) {
+	s := NewStateMachine()
+	i := &Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPrepareUpdate,
+		Active:   marker.NodeActionPrepareUpdate,
+		State:    marker.NodeStateError,
+	}
+
+	got, err := s.Step(i)
+	if err != nil {
+		t.Fatalf("Step() error = %v, want nil", err)
+	}
+	if got.Edge != Retry {
+		t.Errorf("Step().Edge = %v, want %v", got.Edge, Retry)
+	}
+	if got.To.Wanted != marker.NodeActionPrepareUpdate {
+		t.Errorf("unregistered Retry advanced Wanted to %v, want it to stay at %v", got.To.Wanted, marker.NodeActionPrepareUpdate)
+	}
+}
+
+func TestClassifyRealizedIsAdvanceNotAbort(t *testing.T) {
+	// Wanted == Active at a non-terminal action is an entirely ordinary
+	// "just finished this step, awaiting the next command" intent, not a
+	// stuck one, even though Stuck()'s invalidProgress term is also true
+	// for it. Realized must win. An update is available so the guard on
+	// entering PerformUpdate doesn't hold the projection at PrepareUpdate.
+	i := &Intent{
+		NodeName:        "node-a",
+		Wanted:          marker.NodeActionPrepareUpdate,
+		Active:          marker.NodeActionPrepareUpdate,
+		State:           marker.NodeStateReady,
+		UpdateAvailable: marker.NodeUpdateAvailable,
+	}
+	if !i.Realized() {
+		t.Fatal("test fixture intent is not Realized(), fix the fixture")
+	}
+
+	s := NewStateMachine()
+	if got := s.classify(i); got != Advance {
+		t.Errorf("classify() = %v, want %v for a realized, non-terminal intent", got, Advance)
+	}
+}
+
+func TestStepAbortFallbackErrors(t *testing.T) {
+	s := NewStateMachine()
+	// Active has moved ahead to RebootUpdate while Wanted has fallen back
+	// to an earlier action: no forward edge produces this, so it's
+	// genuinely Stuck and not simply Realized.
+	i := &Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPrepareUpdate,
+		Active:   marker.NodeActionRebootUpdate,
+		State:    marker.NodeStateReady,
+	}
+	if i.Realized() {
+		t.Fatal("test fixture intent is Realized(), fix the fixture")
+	}
+	if !i.Stuck() {
+		t.Fatal("test fixture intent is not Stuck(), fix the fixture")
+	}
+
+	_, err := s.Step(i)
+	if err == nil {
+		t.Fatal("Step() on a Stuck intent with no registered Abort edge returned nil error, want an error")
+	}
+}
+
+func TestStepRequiresUpdateAvailableEnteringPerformUpdate(t *testing.T) {
+	// Wanted == Active == PrepareUpdate: an ordinary Realized intent about
+	// to be advanced into PerformUpdate. This is the edge that must be
+	// guarded, not the one leaving PerformUpdate.
+	i := &Intent{
+		NodeName:        "node-a",
+		Wanted:          marker.NodeActionPrepareUpdate,
+		Active:          marker.NodeActionPrepareUpdate,
+		State:           marker.NodeStateReady,
+		UpdateAvailable: marker.NodeUpdateUnknown,
+	}
+	if !i.Realized() {
+		t.Fatal("test fixture intent is not Realized(), fix the fixture")
+	}
+
+	_, err := DefaultStateMachine.Step(i)
+	if err == nil {
+		t.Fatal("Step() entering PerformUpdate with no update available, want guard error")
+	}
+}
+
+func TestNextWantedBlocksEnteringPerformUpdateWithoutAvailableUpdate(t *testing.T) {
+	i := &Intent{NodeName: "node-a", UpdateAvailable: marker.NodeUpdateUnknown}
+
+	_, err := DefaultStateMachine.NextWanted(i, marker.NodeActionPrepareUpdate)
+	if err == nil {
+		t.Fatal("NextWanted(PrepareUpdate) with no update available, want guard error")
+	}
+}
+
+func TestProjectedBlocksEnteringPerformUpdateWithoutAvailableUpdate(t *testing.T) {
+	i := &Intent{
+		NodeName:        "node-a",
+		Wanted:          marker.NodeActionPrepareUpdate,
+		Active:          marker.NodeActionPrepareUpdate,
+		State:           marker.NodeStateReady,
+		UpdateAvailable: marker.NodeUpdateUnknown,
+	}
+
+	p := i.Projected()
+	if p.Wanted != marker.NodeActionPrepareUpdate {
+		t.Errorf("Projected().Wanted = %v, want it to stay at %v since the guard should block the advance", p.Wanted, marker.NodeActionPrepareUpdate)
+	}
+}
+
+func TestNextWantedFallsBackToCalculateNext(t *testing.T) {
+	s := NewStateMachine()
+	i := &Intent{NodeName: "node-a", Wanted: marker.NodeActionReset}
+
+	want, wantErr := calculateNext(marker.NodeActionReset)
+	got, err := s.NextWanted(i, marker.NodeActionReset)
+	if err != wantErr || got != want {
+		t.Errorf("NextWanted() = (%v, %v), want (%v, %v)", got, err, want, wantErr)
+	}
+}
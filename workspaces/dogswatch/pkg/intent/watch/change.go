@@ -0,0 +1,85 @@
+package watch
+
+import (
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+// Kind classifies the semantic meaning of an observed Intent change, derived
+// from the same predicates a controller would otherwise apply to every Node
+// update individually.
+type Kind string
+
+const (
+	// Added indicates the Node was newly observed.
+	Added Kind = "Added"
+	// Modified indicates the Node's Intent changed, but not in a way any
+	// other Kind more specifically describes.
+	Modified Kind = "Modified"
+	// Reset indicates the Node's Intent returned to its Origin.
+	Reset Kind = "Reset"
+	// Realized indicates the Node's Intent reached its Wanted action.
+	Realized Kind = "Realized"
+	// Stuck indicates the Node's Intent cannot make further progress without
+	// outside intervention.
+	Stuck Kind = "Stuck"
+	// Errored indicates the Node's Intent recorded an error on its Active
+	// action.
+	Errored Kind = "Errored"
+)
+
+// IntentChange carries the before and after Intent observed for a Node,
+// along with the Kind of change between them. Old is nil for an Added
+// change.
+type IntentChange struct {
+	Old  *intent.Intent
+	New  *intent.Intent
+	Kind Kind
+}
+
+// classify determines the Kind of change between an Intent and its prior
+// observation. old is nil when next was just Added.
+func classify(old, next *intent.Intent) Kind {
+	switch {
+	case old == nil:
+		return Added
+	case wasReset(old, next):
+		// Checked ahead of Errored/Stuck: a freshly reset Intent almost
+		// always has Stuck() == true too (the reset state projects forward
+		// to the first real action), so Reset must win or it's unreachable.
+		return Reset
+	case next.Errored():
+		return Errored
+	case next.Realized():
+		// Checked ahead of Stuck: Stuck's invalidProgress term is true for
+		// any non-terminal Wanted == Active pair, which is also exactly the
+		// shape of an ordinary successful step completion, not only a
+		// genuinely broken intent.
+		return Realized
+	case next.Stuck():
+		return Stuck
+	default:
+		return Modified
+	}
+}
+
+// wasReset reports whether next reflects a freshly reset Intent that had
+// previously made some progress.
+func wasReset(old, next *intent.Intent) bool {
+	reset := next.Wanted == marker.NodeActionUnknown && next.State == marker.NodeStateUnknown
+	hadProgress := old.Wanted != marker.NodeActionUnknown || old.Active != marker.NodeActionUnknown
+	return reset && hadProgress
+}
+
+// noop reports whether next is not semantically different from old, so the
+// Watcher can collapse redundant updates instead of waking consumers on
+// every reprocessed Node.
+func noop(old, next *intent.Intent) bool {
+	if old == nil {
+		return false
+	}
+	return old.Wanted == next.Wanted &&
+		old.Active == next.Active &&
+		old.State == next.State &&
+		old.UpdateAvailable == next.UpdateAvailable
+}
@@ -0,0 +1,70 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+func TestClassifyResetWinsOverStuck(t *testing.T) {
+	old := &intent.Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPerformUpdate,
+		Active:   marker.NodeActionPrepareUpdate,
+		State:    marker.NodeStateReady,
+	}
+	next := &intent.Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionUnknown,
+		Active:   marker.NodeActionUnknown,
+		State:    marker.NodeStateUnknown,
+	}
+
+	if !next.Stuck() {
+		t.Fatal("test fixture's reset intent is not Stuck(), fix the fixture to match the bug this test guards")
+	}
+
+	if got, want := classify(old, next), Reset; got != want {
+		t.Errorf("classify() = %v, want %v (Reset must win over Stuck for a freshly reset intent)", got, want)
+	}
+}
+
+func TestClassifyAdded(t *testing.T) {
+	next := &intent.Intent{NodeName: "node-a"}
+	if got, want := classify(nil, next), Added; got != want {
+		t.Errorf("classify(nil, ...) = %v, want %v", got, want)
+	}
+}
+
+func TestClassifyRealizedOnGenuineCompletion(t *testing.T) {
+	old := &intent.Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPrepareUpdate,
+		Active:   marker.NodeActionReset,
+		State:    marker.NodeStateReady,
+	}
+	next := &intent.Intent{
+		NodeName: "node-a",
+		Wanted:   marker.NodeActionPrepareUpdate,
+		Active:   marker.NodeActionPrepareUpdate,
+		State:    marker.NodeStateReady,
+	}
+
+	if !next.Realized() {
+		t.Fatal("test fixture's completed intent is not Realized(), fix the fixture")
+	}
+
+	if got, want := classify(old, next), Realized; got != want {
+		t.Errorf("classify() = %v, want %v (Realized must win over Stuck for a genuine completion)", got, want)
+	}
+}
+
+func TestClassifyErrored(t *testing.T) {
+	old := &intent.Intent{NodeName: "node-a", Wanted: marker.NodeActionPrepareUpdate, Active: marker.NodeActionPrepareUpdate, State: marker.NodeStateReady}
+	next := &intent.Intent{NodeName: "node-a", Wanted: marker.NodeActionPrepareUpdate, Active: marker.NodeActionPrepareUpdate, State: marker.NodeStateError}
+
+	if got, want := classify(old, next), Errored; got != want {
+		t.Errorf("classify() = %v, want %v", got, want)
+	}
+}
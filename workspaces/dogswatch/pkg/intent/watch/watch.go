@@ -0,0 +1,111 @@
+// Package watch streams classified Intent transitions off of a Node
+// informer, so controllers can react to semantically meaningful changes
+// instead of reprocessing every Node update themselves.
+package watch
+
+import (
+	"log"
+	"sync/atomic"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+)
+
+// changeBuffer bounds how many IntentChanges may be queued on the Changes
+// channel before further changes are dropped rather than sent.
+const changeBuffer = 64
+
+// Informer is the subset of cache.SharedIndexInformer's registration surface
+// the Watcher needs, satisfied by the informers the controller already runs
+// for Nodes.
+type Informer interface {
+	AddEventHandler(handler cache.ResourceEventHandler)
+}
+
+// Watcher diffs successive Given() results off of a Node informer and
+// emits only the changes that are semantically meaningful.
+type Watcher struct {
+	changes chan IntentChange
+	last    map[string]*intent.Intent
+	dropped uint64
+}
+
+// NewIntentWatcher registers a handler on the provided informer and returns
+// a Watcher whose Changes channel emits classified IntentChanges.
+func NewIntentWatcher(informer Informer) *Watcher {
+	w := &Watcher{
+		changes: make(chan IntentChange, changeBuffer),
+		last:    make(map[string]*intent.Intent),
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.handleAdd,
+		UpdateFunc: w.handleUpdate,
+		DeleteFunc: w.handleDelete,
+	})
+	return w
+}
+
+// Changes returns the channel of classified IntentChanges. Callers should
+// range over it for the life of the Watcher.
+func (w *Watcher) Changes() <-chan IntentChange {
+	return w.changes
+}
+
+func (w *Watcher) handleAdd(obj interface{}) {
+	input, ok := asInput(obj)
+	if !ok {
+		return
+	}
+	next := intent.Given(input)
+	w.emit(nil, next)
+}
+
+func (w *Watcher) handleUpdate(oldObj, newObj interface{}) {
+	newInput, ok := asInput(newObj)
+	if !ok {
+		return
+	}
+	next := intent.Given(newInput)
+	prior := w.last[next.GetName()]
+	w.emit(prior, next)
+}
+
+func (w *Watcher) handleDelete(obj interface{}) {
+	if input, ok := asInput(obj); ok {
+		delete(w.last, input.GetName())
+	}
+}
+
+func (w *Watcher) emit(old, next *intent.Intent) {
+	if noop(old, next) {
+		return
+	}
+	w.last[next.GetName()] = next
+	change := IntentChange{Old: old, New: next, Kind: classify(old, next)}
+	// A blocking send here would stall the shared informer's event
+	// processing goroutine if a consumer falls behind, so drop and log
+	// instead of backing up indefinitely.
+	select {
+	case w.changes <- change:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+		log.Printf("intent/watch: dropping %s change for %q, Changes() consumer is falling behind", change.Kind, next.GetName())
+	}
+}
+
+// Dropped reports how many IntentChanges have been dropped because a
+// consumer wasn't draining Changes() quickly enough.
+func (w *Watcher) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// asInput recovers the intent.Input from an informer event's object,
+// unwrapping a deleted object's final known state if necessary.
+func asInput(obj interface{}) (intent.Input, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	input, ok := obj.(intent.Input)
+	return input, ok
+}
@@ -0,0 +1,79 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/amazonlinux/thar/dogswatch/pkg/intent"
+	"github.com/amazonlinux/thar/dogswatch/pkg/marker"
+)
+
+type fakeNode struct {
+	name  string
+	annos map[string]string
+}
+
+func (f *fakeNode) GetName() string                  { return f.name }
+func (f *fakeNode) GetAnnotations() map[string]string { return f.annos }
+func (f *fakeNode) GetLabels() map[string]string      { return nil }
+
+var _ intent.Input = (*fakeNode)(nil)
+
+func TestEmitDoesNotBlockWhenConsumerFallsBehind(t *testing.T) {
+	w := &Watcher{
+		changes: make(chan IntentChange), // unbuffered: any reader-less send blocks
+		last:    make(map[string]*intent.Intent),
+	}
+	node := &fakeNode{name: "node-a", annos: map[string]string{
+		marker.NodeActionWanted: marker.NodeActionPrepareUpdate,
+		marker.NodeActionActive: marker.NodeActionPrepareUpdate,
+	}}
+
+	done := make(chan struct{})
+	go func() {
+		w.handleAdd(node)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handleAdd blocked on Changes with no reader; emit must drop instead of blocking")
+	}
+
+	if got, want := w.Dropped(), uint64(1); got != want {
+		t.Errorf("Dropped() = %d, want %d", got, want)
+	}
+}
+
+func TestEmitCollapsesNoopUpdates(t *testing.T) {
+	w := NewIntentWatcher(&fakeInformer{})
+	annos := map[string]string{
+		marker.NodeActionWanted: marker.NodeActionPrepareUpdate,
+		marker.NodeActionActive: marker.NodeActionPrepareUpdate,
+	}
+	node := &fakeNode{name: "node-a", annos: annos}
+
+	w.handleAdd(node)
+	select {
+	case <-w.Changes():
+	default:
+		t.Fatal("expected an Added change to be queued")
+	}
+
+	w.handleUpdate(node, node)
+	select {
+	case c := <-w.Changes():
+		t.Fatalf("expected no-op update to be collapsed, got %+v", c)
+	default:
+	}
+}
+
+// fakeInformer satisfies Informer without wiring up a real shared informer;
+// the tests above drive the Watcher's handlers directly instead of through
+// it.
+type fakeInformer struct{}
+
+func (f *fakeInformer) AddEventHandler(handler cache.ResourceEventHandler) {}